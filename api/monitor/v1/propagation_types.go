@@ -0,0 +1,196 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlarmPropagationPolicy selects a set of member clusters that an
+// AlarmPolicy template should be fanned out to. It never holds alerting
+// rules itself; Spec.AlarmPolicyName names the AlarmPolicy it propagates.
+type AlarmPropagationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   AlarmPropagationPolicySpec   `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+	Status AlarmPropagationPolicyStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// AlarmPropagationPolicySpec describes which clusters an AlarmPolicy
+// should be propagated to.
+type AlarmPropagationPolicySpec struct {
+	// AlarmPolicyName is the name of the (cluster-scoped) AlarmPolicy to
+	// use as the propagation template.
+	AlarmPolicyName string `json:"alarmPolicyName" protobuf:"bytes,1,opt,name=alarmPolicyName"`
+
+	// ClusterSelector selects the platformv1.Cluster objects this policy
+	// should be propagated to. An empty selector matches every cluster.
+	ClusterSelector ClusterSelector `json:"clusterSelector,omitempty" protobuf:"bytes,2,opt,name=clusterSelector"`
+
+	// ClusterOverrides allows a per-cluster patch of the rendered
+	// AlarmPolicy, keyed by cluster name.
+	// +optional
+	ClusterOverrides []ClusterOverride `json:"clusterOverrides,omitempty" protobuf:"bytes,3,rep,name=clusterOverrides"`
+}
+
+// ClusterSelector selects platformv1.Cluster objects either by label
+// selector or by an explicit list of cluster names. It has no field
+// selector.
+type ClusterSelector struct {
+	// LabelSelector restricts the match to clusters with matching labels.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty" protobuf:"bytes,1,opt,name=labelSelector"`
+
+	// ClusterNames, if non-empty, restricts the match to exactly these
+	// cluster names regardless of LabelSelector.
+	// +optional
+	ClusterNames []string `json:"clusterNames,omitempty" protobuf:"bytes,2,rep,name=clusterNames"`
+}
+
+// ClusterOverride patches the rendered AlarmPolicy for a single cluster,
+// e.g. to silence a rule in a specific region.
+type ClusterOverride struct {
+	ClusterName string `json:"clusterName" protobuf:"bytes,1,opt,name=clusterName"`
+
+	// Patch is a strategic merge patch applied to the per-cluster
+	// FederatedAlarmPolicy.Spec.Template after rendering.
+	Patch string `json:"patch,omitempty" protobuf:"bytes,2,opt,name=patch"`
+}
+
+// AlarmPropagationPolicyStatus reports the outcome of fanning the policy
+// out to its selected clusters.
+type AlarmPropagationPolicyStatus struct {
+	// ObservedGeneration is the Spec generation this status was computed
+	// from.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,1,opt,name=observedGeneration"`
+
+	// Clusters reports the per-cluster apply status collected from each
+	// matched cluster's FederatedAlarmPolicy.
+	Clusters []CollectedAlarmStatus `json:"clusters,omitempty" protobuf:"bytes,2,rep,name=clusters"`
+}
+
+// CollectedAlarmStatus is the per-cluster apply status rolled up onto an
+// AlarmPropagationPolicy (and, cluster-scoped, onto a
+// ClusterPropagationPolicy) from its FederatedAlarmPolicy objects.
+type CollectedAlarmStatus struct {
+	ClusterName string `json:"clusterName" protobuf:"bytes,1,opt,name=clusterName"`
+
+	// Applied is true once the FederatedAlarmPolicy holding the rendered
+	// AlarmPolicy for ClusterName has been successfully created/updated.
+	// It does not report whether the AlarmPolicy exists in the member
+	// cluster ClusterName itself.
+	Applied bool `json:"applied" protobuf:"varint,2,opt,name=applied"`
+
+	// Reason and Message explain a non-Applied state, mirroring
+	// platformv1.ClusterCondition's convention.
+	Reason  string `json:"reason,omitempty" protobuf:"bytes,3,opt,name=reason"`
+	Message string `json:"message,omitempty" protobuf:"bytes,4,opt,name=message"`
+
+	// LastTransitionTime is when Applied last changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,5,opt,name=lastTransitionTime"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlarmPropagationPolicyList is a list of AlarmPropagationPolicy.
+type AlarmPropagationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []AlarmPropagationPolicy `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterPropagationPolicy is the cluster-scoped variant of
+// AlarmPropagationPolicy. The FederatedAlarmPolicy objects it renders are
+// created in the kube-system namespace rather than a policy namespace.
+type ClusterPropagationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   AlarmPropagationPolicySpec   `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+	Status AlarmPropagationPolicyStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterPropagationPolicyList is a list of ClusterPropagationPolicy.
+type ClusterPropagationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []ClusterPropagationPolicy `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FederatedAlarmPolicy is the per-cluster rendering of an AlarmPolicy
+// template produced by an AlarmPropagationPolicy. It lives alongside the
+// AlarmPropagationPolicy in the host cluster (not in the member cluster
+// itself) so its Status can be watched and reconciled the same way any
+// other TKE resource is.
+type FederatedAlarmPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   FederatedAlarmPolicySpec   `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+	Status FederatedAlarmPolicyStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// FederatedAlarmPolicySpec holds the fully-rendered, per-cluster override
+// of the source AlarmPolicy.
+type FederatedAlarmPolicySpec struct {
+	// ClusterName is the member cluster this rendering targets.
+	ClusterName string `json:"clusterName" protobuf:"bytes,1,opt,name=clusterName"`
+
+	// PropagationPolicyName is the AlarmPropagationPolicy (or
+	// ClusterPropagationPolicy) that produced this object.
+	PropagationPolicyName string `json:"propagationPolicyName" protobuf:"bytes,2,opt,name=propagationPolicyName"`
+
+	// Template is the rendered AlarmPolicySpec, after applying the
+	// matching ClusterOverride (if any), to create/update in ClusterName.
+	Template AlarmPolicySpec `json:"template" protobuf:"bytes,3,opt,name=template"`
+}
+
+// FederatedAlarmPolicyStatus reports whether this FederatedAlarmPolicy
+// has been written with Spec.Template for Spec.ClusterName (not whether
+// the AlarmPolicy exists in that member cluster), and is collected back
+// onto the owning
+// AlarmPropagationPolicy as a CollectedAlarmStatus entry.
+type FederatedAlarmPolicyStatus struct {
+	CollectedAlarmStatus `json:",inline"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FederatedAlarmPolicyList is a list of FederatedAlarmPolicy.
+type FederatedAlarmPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []FederatedAlarmPolicy `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -0,0 +1,474 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package alarmpolicypropagation fans an AlarmPolicy template out to a set
+// of member clusters selected by an AlarmPropagationPolicy (or its
+// cluster-scoped variant, a ClusterPropagationPolicy), rendering one
+// FederatedAlarmPolicy per matched cluster and reconciling drift back to
+// the desired state.
+package alarmpolicypropagation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	monitorversionedclient "tkestack.io/tke/api/client/clientset/versioned/typed/monitor/v1"
+	platformversionedclient "tkestack.io/tke/api/client/clientset/versioned/typed/platform/v1"
+	monitorv1 "tkestack.io/tke/api/monitor/v1"
+	platformv1 "tkestack.io/tke/api/platform/v1"
+	controllerutil "tkestack.io/tke/pkg/controller"
+	"tkestack.io/tke/pkg/util/log"
+)
+
+// federatedAlarmPolicyOwnerLabel records which AlarmPropagationPolicy or
+// ClusterPropagationPolicy rendered a FederatedAlarmPolicy, so drift
+// detection can list exactly the set this controller owns.
+const federatedAlarmPolicyOwnerLabel = "monitor.tkestack.io/propagation-policy"
+
+// federatedAlarmPolicyNamespace is where FederatedAlarmPolicy objects are
+// created for cluster-scoped ClusterPropagationPolicy sources, since
+// FederatedAlarmPolicy is itself namespaced. AlarmPropagationPolicy
+// sources instead use their own namespace.
+const federatedAlarmPolicyNamespace = metav1.NamespaceSystem
+
+// clusterPolicyKeyPrefix distinguishes ClusterPropagationPolicy keys from
+// AlarmPropagationPolicy keys on the shared workqueue, since the two
+// informers would otherwise produce colliding keys for same-named
+// objects.
+const clusterPolicyKeyPrefix = "cluster/"
+
+// Controller fans out AlarmPropagationPolicy and ClusterPropagationPolicy
+// objects to FederatedAlarmPolicy objects, one per matched cluster,
+// following the same workqueue-driven reconcile pattern used by the
+// platform cluster controller.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	indexer  cache.Indexer
+	informer cache.Controller
+
+	clusterIndexer  cache.Indexer
+	clusterInformer cache.Controller
+
+	log            log.Logger
+	monitorClient  monitorversionedclient.MonitorV1Interface
+	platformClient platformversionedclient.PlatformV1Interface
+}
+
+// NewController creates a new Controller object.
+func NewController(
+	monitorClient monitorversionedclient.MonitorV1Interface,
+	platformClient platformversionedclient.PlatformV1Interface,
+	resyncPeriod time.Duration) *Controller {
+	c := &Controller{
+		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "alarmPolicyPropagation"),
+		log:            log.WithName("alarm-policy-propagation-controller"),
+		monitorClient:  monitorClient,
+		platformClient: platformClient,
+	}
+
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return monitorClient.AlarmPropagationPolicies(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return monitorClient.AlarmPropagationPolicies(metav1.NamespaceAll).Watch(options)
+		},
+	}
+
+	c.indexer, c.informer = cache.NewIndexerInformer(listWatch, &monitorv1.AlarmPropagationPolicy{}, resyncPeriod,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueueObj,
+			UpdateFunc: func(old, new interface{}) { c.enqueueObj(new) },
+			DeleteFunc: c.enqueueObj,
+		},
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+
+	clusterListWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return monitorClient.ClusterPropagationPolicies().List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return monitorClient.ClusterPropagationPolicies().Watch(options)
+		},
+	}
+
+	c.clusterIndexer, c.clusterInformer = cache.NewIndexerInformer(clusterListWatch, &monitorv1.ClusterPropagationPolicy{}, resyncPeriod,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueueClusterObj,
+			UpdateFunc: func(old, new interface{}) { c.enqueueClusterObj(new) },
+			DeleteFunc: c.enqueueClusterObj,
+		},
+		cache.Indexers{},
+	)
+
+	return c
+}
+
+func (c *Controller) enqueueObj(obj interface{}) {
+	key, err := controllerutil.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %+v: %v", obj, err))
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueClusterObj is enqueueObj's ClusterPropagationPolicy counterpart,
+// prefixing the key so processNextWorkItem can tell the two object kinds
+// apart on the shared queue.
+func (c *Controller) enqueueClusterObj(obj interface{}) {
+	key, err := controllerutil.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %+v: %v", obj, err))
+		return
+	}
+	c.queue.Add(clusterPolicyKeyPrefix + key)
+}
+
+// Run starts the controller's workqueue-driven reconcile loop.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	log.Info("Starting alarm policy propagation controller")
+	defer log.Info("Shutting down alarm policy propagation controller")
+
+	go c.informer.Run(stopCh)
+	go c.clusterInformer.Run(stopCh)
+
+	if ok := cache.WaitForCacheSync(stopCh, c.informer.HasSynced, c.clusterInformer.HasSynced); !ok {
+		return fmt.Errorf("failed to wait for alarm propagation policy caches to sync")
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+	return nil
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	var err error
+	if k := key.(string); strings.HasPrefix(k, clusterPolicyKeyPrefix) {
+		err = c.syncClusterPolicy(strings.TrimPrefix(k, clusterPolicyKeyPrefix))
+	} else {
+		err = c.syncPolicy(k)
+	}
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("error processing alarm propagation policy %v (will retry): %v", key, err))
+	c.queue.AddRateLimited(key)
+	return true
+}
+
+// syncPolicy renders and applies one FederatedAlarmPolicy per cluster
+// matched by the AlarmPropagationPolicy named by key, then reconciles
+// away any FederatedAlarmPolicy this policy previously created for a
+// cluster that's no longer selected, and finally rolls the per-cluster
+// apply outcomes up into the policy's Status. Once the policy itself has
+// been deleted, every FederatedAlarmPolicy it owns is pruned instead of
+// being left behind.
+func (c *Controller) syncPolicy(key string) error {
+	obj, exists, err := c.indexer.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return err
+		}
+		c.log.Info("AlarmPropagationPolicy has been deleted, pruning its FederatedAlarmPolicies", "key", key)
+		return c.pruneStaleFederatedAlarmPolicies(namespace, name, nil)
+	}
+	policy := obj.(*monitorv1.AlarmPropagationPolicy).DeepCopy()
+
+	collected, err := c.syncPropagation(policy.Namespace, policy.Name, policy.Spec)
+	if err != nil {
+		return err
+	}
+
+	policy.Status.ObservedGeneration = policy.Generation
+	policy.Status.Clusters = collected
+	if _, err := c.monitorClient.AlarmPropagationPolicies(policy.Namespace).UpdateStatus(policy); err != nil {
+		return fmt.Errorf("update AlarmPropagationPolicy status error: %w", err)
+	}
+
+	return nil
+}
+
+// syncClusterPolicy is syncPolicy's ClusterPropagationPolicy counterpart:
+// the same fan-out and prune logic, but sourced from a cluster-scoped
+// policy and landing its FederatedAlarmPolicy objects in
+// federatedAlarmPolicyNamespace instead of the policy's own namespace.
+func (c *Controller) syncClusterPolicy(key string) error {
+	obj, exists, err := c.clusterIndexer.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		c.log.Info("ClusterPropagationPolicy has been deleted, pruning its FederatedAlarmPolicies", "key", key)
+		return c.pruneStaleFederatedAlarmPolicies(federatedAlarmPolicyNamespace, key, nil)
+	}
+	policy := obj.(*monitorv1.ClusterPropagationPolicy).DeepCopy()
+
+	collected, err := c.syncPropagation(federatedAlarmPolicyNamespace, policy.Name, policy.Spec)
+	if err != nil {
+		return err
+	}
+
+	policy.Status.ObservedGeneration = policy.Generation
+	policy.Status.Clusters = collected
+	if _, err := c.monitorClient.ClusterPropagationPolicies().UpdateStatus(policy); err != nil {
+		return fmt.Errorf("update ClusterPropagationPolicy status error: %w", err)
+	}
+
+	return nil
+}
+
+// syncPropagation renders and applies one FederatedAlarmPolicy per
+// cluster matched by spec into namespace (using ownerName as both the
+// FederatedAlarmPolicy owner label and name prefix), then prunes any
+// FederatedAlarmPolicy previously created for a cluster no longer
+// selected. It is shared by the namespaced AlarmPropagationPolicy and
+// cluster-scoped ClusterPropagationPolicy sync paths, which differ only
+// in where their source object and FederatedAlarmPolicy objects live.
+func (c *Controller) syncPropagation(namespace, ownerName string, spec monitorv1.AlarmPropagationPolicySpec) ([]monitorv1.CollectedAlarmStatus, error) {
+	ctx := context.Background()
+
+	alarmPolicy, err := c.monitorClient.AlarmPolicies().Get(spec.AlarmPolicyName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get source AlarmPolicy %q error: %w", spec.AlarmPolicyName, err)
+	}
+
+	clusters, err := c.matchedClusters(ctx, spec.ClusterSelector)
+	if err != nil {
+		return nil, fmt.Errorf("list matched clusters error: %w", err)
+	}
+
+	overrides := make(map[string]string, len(spec.ClusterOverrides))
+	for _, o := range spec.ClusterOverrides {
+		overrides[o.ClusterName] = o.Patch
+	}
+
+	wantClusters := make(map[string]struct{}, len(clusters))
+	collected := make([]monitorv1.CollectedAlarmStatus, 0, len(clusters))
+	for _, cluster := range clusters {
+		wantClusters[cluster.Name] = struct{}{}
+
+		status, applyErr := c.applyFederatedAlarmPolicy(namespace, ownerName, alarmPolicy, cluster.Name, overrides[cluster.Name])
+		if applyErr != nil {
+			utilruntime.HandleError(fmt.Errorf("apply FederatedAlarmPolicy for cluster %q error: %v", cluster.Name, applyErr))
+		}
+		collected = append(collected, status)
+	}
+
+	if err := c.pruneStaleFederatedAlarmPolicies(namespace, ownerName, wantClusters); err != nil {
+		return nil, fmt.Errorf("prune stale FederatedAlarmPolicy error: %w", err)
+	}
+
+	return collected, nil
+}
+
+// matchedClusters lists the platformv1.Cluster objects selected by
+// selector, honoring ClusterNames as an override of LabelSelector.
+func (c *Controller) matchedClusters(ctx context.Context, selector monitorv1.ClusterSelector) ([]platformv1.Cluster, error) {
+	if len(selector.ClusterNames) > 0 {
+		clusters := make([]platformv1.Cluster, 0, len(selector.ClusterNames))
+		for _, name := range selector.ClusterNames {
+			cluster, err := c.platformClient.Clusters().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			clusters = append(clusters, *cluster)
+		}
+		return clusters, nil
+	}
+
+	listOptions := metav1.ListOptions{}
+	if selector.LabelSelector != nil {
+		set, err := metav1.LabelSelectorAsSelector(selector.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		listOptions.LabelSelector = set.String()
+	} else {
+		listOptions.LabelSelector = labels.Everything().String()
+	}
+
+	list, err := c.platformClient.Clusters().List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// applyFederatedAlarmPolicy renders the AlarmPolicy template for
+// clusterName (applying patch, if any) and creates or updates the
+// matching FederatedAlarmPolicy in namespace, returning the resulting
+// apply status.
+func (c *Controller) applyFederatedAlarmPolicy(namespace, ownerName string, alarmPolicy *monitorv1.AlarmPolicy, clusterName, patch string) (monitorv1.CollectedAlarmStatus, error) {
+	status := monitorv1.CollectedAlarmStatus{
+		ClusterName:        clusterName,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	name := federatedAlarmPolicyName(ownerName, clusterName)
+	existing, err := c.monitorClient.FederatedAlarmPolicies(namespace).Get(name, metav1.GetOptions{})
+
+	// keepTransitionTime carries the previous LastTransitionTime over when
+	// Applied has not changed since the last recorded status.
+	found := err == nil
+	keepTransitionTime := func() {
+		if found && existing.Status.Applied == status.Applied && !existing.Status.LastTransitionTime.IsZero() {
+			status.LastTransitionTime = existing.Status.LastTransitionTime
+		}
+	}
+
+	template := alarmPolicy.Spec
+	if patch != "" {
+		var err error
+		template, err = applyTemplatePatch(template, patch)
+		if err != nil {
+			status.Reason = "RenderFailed"
+			status.Message = err.Error()
+			keepTransitionTime()
+			c.updateFederatedAlarmPolicyStatus(namespace, name, status)
+			return status, err
+		}
+	}
+
+	federated := &monitorv1.FederatedAlarmPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{federatedAlarmPolicyOwnerLabel: ownerName},
+		},
+		Spec: monitorv1.FederatedAlarmPolicySpec{
+			ClusterName:           clusterName,
+			PropagationPolicyName: ownerName,
+			Template:              template,
+		},
+	}
+
+	var applied *monitorv1.FederatedAlarmPolicy
+	if apierrors.IsNotFound(err) {
+		applied, err = c.monitorClient.FederatedAlarmPolicies(namespace).Create(federated)
+	} else if err == nil {
+		federated.ResourceVersion = existing.ResourceVersion
+		applied, err = c.monitorClient.FederatedAlarmPolicies(namespace).Update(federated)
+	}
+
+	if err != nil {
+		status.Applied = false
+		status.Reason = "ApplyFailed"
+		status.Message = err.Error()
+		keepTransitionTime()
+		c.updateFederatedAlarmPolicyStatus(namespace, name, status)
+		return status, err
+	}
+
+	status.Applied = true
+	keepTransitionTime()
+	applied.Status = monitorv1.FederatedAlarmPolicyStatus{CollectedAlarmStatus: status}
+	if _, err := c.monitorClient.FederatedAlarmPolicies(namespace).UpdateStatus(applied); err != nil {
+		return status, fmt.Errorf("update FederatedAlarmPolicy %q status error: %w", name, err)
+	}
+
+	return status, nil
+}
+
+// updateFederatedAlarmPolicyStatus best-effort persists status onto the
+// FederatedAlarmPolicy named name, for the render-failure and
+// apply-failure paths where applyFederatedAlarmPolicy has no successful
+// Create/Update result to carry it. A failure here is logged rather than
+// returned, since the caller already has the real error (the render or
+// apply failure) to report.
+func (c *Controller) updateFederatedAlarmPolicyStatus(namespace, name string, status monitorv1.CollectedAlarmStatus) {
+	existing, err := c.monitorClient.FederatedAlarmPolicies(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			utilruntime.HandleError(fmt.Errorf("get FederatedAlarmPolicy %q for status update error: %v", name, err))
+		}
+		return
+	}
+	existing.Status = monitorv1.FederatedAlarmPolicyStatus{CollectedAlarmStatus: status}
+	if _, err := c.monitorClient.FederatedAlarmPolicies(namespace).UpdateStatus(existing); err != nil {
+		utilruntime.HandleError(fmt.Errorf("update FederatedAlarmPolicy %q status error: %v", name, err))
+	}
+}
+
+// pruneStaleFederatedAlarmPolicies deletes any FederatedAlarmPolicy owned
+// by ownerName in namespace that isn't for a cluster in wantClusters. A
+// nil wantClusters (the owning policy has been deleted) prunes every
+// FederatedAlarmPolicy it owns.
+func (c *Controller) pruneStaleFederatedAlarmPolicies(namespace, ownerName string, wantClusters map[string]struct{}) error {
+	owned, err := c.monitorClient.FederatedAlarmPolicies(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", federatedAlarmPolicyOwnerLabel, ownerName),
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range owned.Items {
+		fed := &owned.Items[i]
+		if _, ok := wantClusters[fed.Spec.ClusterName]; ok {
+			continue
+		}
+		if err := c.monitorClient.FederatedAlarmPolicies(namespace).Delete(fed.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func federatedAlarmPolicyName(ownerName, clusterName string) string {
+	return fmt.Sprintf("%s-%s", ownerName, clusterName)
+}
@@ -0,0 +1,166 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package alarmpolicypropagation
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	monitorversionedclient "tkestack.io/tke/api/client/clientset/versioned/typed/monitor/v1"
+	monitorv1 "tkestack.io/tke/api/monitor/v1"
+)
+
+// fakeFederatedAlarmPolicies is a minimal, test-local
+// FederatedAlarmPolicyInterface backed by an in-memory map, since this
+// tree has no generated fake clientset for the monitor.tkestack.io group.
+// Only List and Delete are exercised by pruneStaleFederatedAlarmPolicies;
+// every other method panics if called.
+type fakeFederatedAlarmPolicies struct {
+	objects map[string]*monitorv1.FederatedAlarmPolicy
+}
+
+func (f *fakeFederatedAlarmPolicies) List(opts metav1.ListOptions) (*monitorv1.FederatedAlarmPolicyList, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list := &monitorv1.FederatedAlarmPolicyList{}
+	for _, obj := range f.objects {
+		if selector.Matches(labels.Set(obj.Labels)) {
+			list.Items = append(list.Items, *obj)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeFederatedAlarmPolicies) Delete(name string, options *metav1.DeleteOptions) error {
+	if _, ok := f.objects[name]; !ok {
+		return apierrors.NewNotFound(schema.GroupResource{Group: "monitor.tkestack.io", Resource: "federatedalarmpolicies"}, name)
+	}
+	delete(f.objects, name)
+	return nil
+}
+
+func (f *fakeFederatedAlarmPolicies) Create(*monitorv1.FederatedAlarmPolicy) (*monitorv1.FederatedAlarmPolicy, error) {
+	panic("not implemented")
+}
+func (f *fakeFederatedAlarmPolicies) Update(*monitorv1.FederatedAlarmPolicy) (*monitorv1.FederatedAlarmPolicy, error) {
+	panic("not implemented")
+}
+func (f *fakeFederatedAlarmPolicies) UpdateStatus(*monitorv1.FederatedAlarmPolicy) (*monitorv1.FederatedAlarmPolicy, error) {
+	panic("not implemented")
+}
+func (f *fakeFederatedAlarmPolicies) Get(name string, options metav1.GetOptions) (*monitorv1.FederatedAlarmPolicy, error) {
+	panic("not implemented")
+}
+func (f *fakeFederatedAlarmPolicies) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	panic("not implemented")
+}
+func (f *fakeFederatedAlarmPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*monitorv1.FederatedAlarmPolicy, error) {
+	panic("not implemented")
+}
+
+// fakeMonitorClient implements monitorversionedclient.MonitorV1Interface,
+// routing FederatedAlarmPolicies to a fixed fake regardless of namespace -
+// the prune tests below only ever use one. Every other Getter panics if
+// called, since pruneStaleFederatedAlarmPolicies never needs them.
+type fakeMonitorClient struct {
+	federated *fakeFederatedAlarmPolicies
+}
+
+func (f *fakeMonitorClient) RESTClient() rest.Interface { panic("not implemented") }
+func (f *fakeMonitorClient) AlarmPolicies() monitorversionedclient.AlarmPolicyInterface {
+	panic("not implemented")
+}
+func (f *fakeMonitorClient) AlarmPropagationPolicies(namespace string) monitorversionedclient.AlarmPropagationPolicyInterface {
+	panic("not implemented")
+}
+func (f *fakeMonitorClient) ClusterPropagationPolicies() monitorversionedclient.ClusterPropagationPolicyInterface {
+	panic("not implemented")
+}
+func (f *fakeMonitorClient) FederatedAlarmPolicies(namespace string) monitorversionedclient.FederatedAlarmPolicyInterface {
+	return f.federated
+}
+
+var _ monitorversionedclient.MonitorV1Interface = (*fakeMonitorClient)(nil)
+
+func TestPruneStaleFederatedAlarmPoliciesDeletesUnwantedClusters(t *testing.T) {
+	federated := &fakeFederatedAlarmPolicies{objects: map[string]*monitorv1.FederatedAlarmPolicy{
+		"owner-a": {
+			ObjectMeta: metav1.ObjectMeta{Name: "owner-a", Labels: map[string]string{federatedAlarmPolicyOwnerLabel: "owner"}},
+			Spec:       monitorv1.FederatedAlarmPolicySpec{ClusterName: "a"},
+		},
+		"owner-b": {
+			ObjectMeta: metav1.ObjectMeta{Name: "owner-b", Labels: map[string]string{federatedAlarmPolicyOwnerLabel: "owner"}},
+			Spec:       monitorv1.FederatedAlarmPolicySpec{ClusterName: "b"},
+		},
+		"other-a": {
+			ObjectMeta: metav1.ObjectMeta{Name: "other-a", Labels: map[string]string{federatedAlarmPolicyOwnerLabel: "other"}},
+			Spec:       monitorv1.FederatedAlarmPolicySpec{ClusterName: "a"},
+		},
+	}}
+
+	c := &Controller{monitorClient: &fakeMonitorClient{federated: federated}}
+
+	if err := c.pruneStaleFederatedAlarmPolicies("ns", "owner", map[string]struct{}{"a": {}}); err != nil {
+		t.Fatalf("pruneStaleFederatedAlarmPolicies returned error: %v", err)
+	}
+
+	if _, ok := federated.objects["owner-a"]; !ok {
+		t.Error("owner-a (still wanted) was deleted")
+	}
+	if _, ok := federated.objects["owner-b"]; ok {
+		t.Error("owner-b (no longer wanted) was not deleted")
+	}
+	if _, ok := federated.objects["other-a"]; !ok {
+		t.Error("other-a (owned by a different policy) was deleted")
+	}
+}
+
+func TestPruneStaleFederatedAlarmPoliciesNilWantClustersDeletesEverythingOwned(t *testing.T) {
+	federated := &fakeFederatedAlarmPolicies{objects: map[string]*monitorv1.FederatedAlarmPolicy{
+		"owner-a": {
+			ObjectMeta: metav1.ObjectMeta{Name: "owner-a", Labels: map[string]string{federatedAlarmPolicyOwnerLabel: "owner"}},
+			Spec:       monitorv1.FederatedAlarmPolicySpec{ClusterName: "a"},
+		},
+		"other-a": {
+			ObjectMeta: metav1.ObjectMeta{Name: "other-a", Labels: map[string]string{federatedAlarmPolicyOwnerLabel: "other"}},
+			Spec:       monitorv1.FederatedAlarmPolicySpec{ClusterName: "a"},
+		},
+	}}
+
+	c := &Controller{monitorClient: &fakeMonitorClient{federated: federated}}
+
+	if err := c.pruneStaleFederatedAlarmPolicies("ns", "owner", nil); err != nil {
+		t.Fatalf("pruneStaleFederatedAlarmPolicies returned error: %v", err)
+	}
+
+	if _, ok := federated.objects["owner-a"]; ok {
+		t.Error("owner-a was not deleted when the owning policy itself was deleted")
+	}
+	if _, ok := federated.objects["other-a"]; !ok {
+		t.Error("other-a (owned by a different policy) was deleted")
+	}
+}
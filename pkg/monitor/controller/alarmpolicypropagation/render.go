@@ -0,0 +1,49 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package alarmpolicypropagation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	monitorv1 "tkestack.io/tke/api/monitor/v1"
+)
+
+// applyTemplatePatch applies a strategic merge patch (as stored in
+// ClusterOverride.Patch) on top of template, returning the per-cluster
+// rendering used for that cluster's FederatedAlarmPolicy.
+func applyTemplatePatch(template monitorv1.AlarmPolicySpec, patch string) (monitorv1.AlarmPolicySpec, error) {
+	original, err := json.Marshal(template)
+	if err != nil {
+		return template, fmt.Errorf("marshal template error: %w", err)
+	}
+
+	merged, err := strategicpatch.StrategicMergePatch(original, []byte(patch), monitorv1.AlarmPolicySpec{})
+	if err != nil {
+		return template, fmt.Errorf("apply cluster override patch error: %w", err)
+	}
+
+	var rendered monitorv1.AlarmPolicySpec
+	if err := json.Unmarshal(merged, &rendered); err != nil {
+		return template, fmt.Errorf("unmarshal rendered template error: %w", err)
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,265 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	platformversionedclient "tkestack.io/tke/api/client/clientset/versioned/typed/platform/v1"
+	platformv1 "tkestack.io/tke/api/platform/v1"
+	"tkestack.io/tke/pkg/platform/util"
+	"tkestack.io/tke/pkg/util/log"
+)
+
+// defaultInformerResyncPeriod is the resync period used by the informer
+// factory backing each cached cluster's watches.
+const defaultInformerResyncPeriod = 10 * time.Minute
+
+// WatchInput specifies the parameters used when setting up a watch against
+// a member cluster's cache.
+type WatchInput struct {
+	// Name uniquely identifies this watch, so that registering it twice
+	// for the same cluster is a no-op.
+	Name string
+
+	// Cluster is the cluster whose cache should be watched.
+	Cluster *platformv1.Cluster
+
+	// Informer selects the informer to watch out of the cluster's shared
+	// informer factory, e.g. `func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+	//     return f.Core().V1().Nodes().Informer()
+	// }`.
+	Informer func(informers.SharedInformerFactory) cache.SharedIndexInformer
+
+	// EventHandler is invoked for every add/update/delete observed by the
+	// watch.
+	EventHandler cache.ResourceEventHandler
+}
+
+// clusterAccessor holds the lazily-constructed clients and caches for a
+// single member cluster. It is a "stoppable cache": once ctx is cancelled,
+// every goroutine started on its behalf (informers, health checks rooted
+// in this context) exits.
+type clusterAccessor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	clusterName string
+	// cacheKey identifies the cluster UID + ClusterCredentialRef this
+	// accessor was built from, so credential rotation can be detected and
+	// the accessor rebuilt rather than silently going stale.
+	cacheKey string
+
+	client    kubernetes.Interface
+	discovery discovery.DiscoveryInterface
+
+	informerFactory informers.SharedInformerFactory
+
+	mu      sync.Mutex
+	started bool
+	watches map[string]struct{}
+}
+
+func (a *clusterAccessor) stop() {
+	a.cancel()
+}
+
+// ClusterCacheTracker lazily builds and caches a REST client, discovery
+// client and informer cache per platformv1.Cluster, so that the cluster,
+// machine, addon and monitor controllers can share a single connection to
+// each member cluster instead of each calling util.BuildExternalClientSet
+// on their own. Entries are cancelled and rebuilt whenever the backing
+// cluster is deleted or its credentials rotate.
+type ClusterCacheTracker struct {
+	log log.Logger
+
+	platformClient platformversionedclient.PlatformV1Interface
+
+	// mu guards accessors as a whole. A single lock, rather than one per
+	// key, is used deliberately: per-key locks make the delete+recreate
+	// path on credential rotation deadlock-prone when a watch goroutine
+	// and Invalidate race on the same key.
+	mu        sync.Mutex
+	accessors map[string]*clusterAccessor
+}
+
+// NewClusterCacheTracker creates a new ClusterCacheTracker.
+func NewClusterCacheTracker(platformClient platformversionedclient.PlatformV1Interface) *ClusterCacheTracker {
+	return &ClusterCacheTracker{
+		log:            log.WithName("cluster-cache-tracker"),
+		platformClient: platformClient,
+		accessors:      make(map[string]*clusterAccessor),
+	}
+}
+
+// GetClient returns the cached client-go client for clusterName, building
+// and caching one if this is the first access.
+func (t *ClusterCacheTracker) GetClient(ctx context.Context, clusterName string) (kubernetes.Interface, error) {
+	cluster, err := t.platformClient.Clusters().Get(ctx, clusterName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	accessor, err := t.getOrCreateAccessor(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return accessor.client, nil
+}
+
+// Watch registers an informer-backed watch against the member cluster's
+// cache, starting the informer it selects if that informer is not running
+// yet. Watches registered under the same WatchInput.Name are idempotent.
+func (t *ClusterCacheTracker) Watch(ctx context.Context, input WatchInput) error {
+	if input.Cluster == nil {
+		return fmt.Errorf("input.Cluster must not be nil")
+	}
+
+	accessor, err := t.getOrCreateAccessor(ctx, input.Cluster)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster accessor for %q: %w", input.Cluster.Name, err)
+	}
+
+	accessor.mu.Lock()
+	defer accessor.mu.Unlock()
+
+	if _, ok := accessor.watches[input.Name]; ok {
+		return nil
+	}
+
+	input.Informer(accessor.informerFactory).AddEventHandler(input.EventHandler)
+
+	if accessor.watches == nil {
+		accessor.watches = make(map[string]struct{})
+	}
+	accessor.watches[input.Name] = struct{}{}
+
+	// Start only launches informers that are not running yet, so calling
+	// it for every new watch also starts an informer selected after the
+	// first watch.
+	accessor.started = true
+	accessor.informerFactory.Start(accessor.ctx.Done())
+
+	return nil
+}
+
+// Invalidate stops and discards the cached accessor for clusterName, if
+// any. Every goroutine the accessor started (informers, health checks
+// rooted in its context) is stopped as a result. Call this when a cluster
+// is deleted or its ClusterCredentialRef changes.
+func (t *ClusterCacheTracker) Invalidate(clusterName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	accessor, ok := t.accessors[clusterName]
+	if !ok {
+		return
+	}
+	accessor.stop()
+	delete(t.accessors, clusterName)
+}
+
+// getOrCreateAccessor returns the cached accessor for cluster, rebuilding
+// it if absent or if the cluster's credentials have rotated since it was
+// created. Building a client is a network round-trip (util.
+// BuildExternalClientSet talks to the member cluster's apiserver), so it
+// deliberately runs without t held: holding a single process-wide lock
+// across that call would stall GetClient/Watch for every other cluster
+// while one slow or unreachable cluster's client is being built.
+func (t *ClusterCacheTracker) getOrCreateAccessor(ctx context.Context, cluster *platformv1.Cluster) (*clusterAccessor, error) {
+	key := cacheKeyFor(cluster)
+
+	if existing, ok := t.lookupAccessor(cluster.Name, key); ok {
+		return existing, nil
+	}
+
+	client, err := util.BuildExternalClientSet(ctx, cluster, t.platformClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build client for cluster %q: %w", cluster.Name, err)
+	}
+
+	accessorCtx, cancel := context.WithCancel(context.Background())
+	accessor := &clusterAccessor{
+		ctx:             accessorCtx,
+		cancel:          cancel,
+		clusterName:     cluster.Name,
+		cacheKey:        key,
+		client:          client,
+		discovery:       client.Discovery(),
+		informerFactory: informers.NewSharedInformerFactory(client, defaultInformerResyncPeriod),
+		watches:         make(map[string]struct{}),
+	}
+
+	t.mu.Lock()
+	if existing, ok := t.accessors[cluster.Name]; ok && existing.cacheKey == key {
+		// Another caller built an accessor for the same credentials while
+		// we were building ours. Discard ours and use theirs, so that two
+		// concurrent builds don't leave one accessor's informers/watches
+		// started but orphaned from t.accessors.
+		t.mu.Unlock()
+		cancel()
+		return existing, nil
+	}
+	if existing, ok := t.accessors[cluster.Name]; ok {
+		t.log.Info("Cluster credentials changed, rebuilding cache", "clusterName", cluster.Name)
+		existing.stop()
+	}
+	t.accessors[cluster.Name] = accessor
+	t.mu.Unlock()
+
+	go func() {
+		<-accessorCtx.Done()
+		t.log.Info("Stopped cluster cache", "clusterName", cluster.Name)
+	}()
+
+	return accessor, nil
+}
+
+// lookupAccessor returns the cached accessor for clusterName if it exists
+// and was built from the given cacheKey.
+func (t *ClusterCacheTracker) lookupAccessor(clusterName, key string) (*clusterAccessor, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	existing, ok := t.accessors[clusterName]
+	if !ok || existing.cacheKey != key {
+		return nil, false
+	}
+	return existing, true
+}
+
+// cacheKeyFor identifies the credentials an accessor was built from, so
+// rotation can be detected without tearing the cache down on every
+// resync.
+func cacheKeyFor(cluster *platformv1.Cluster) string {
+	credentialRef := ""
+	if cluster.Spec.ClusterCredentialRef != nil {
+		credentialRef = cluster.Spec.ClusterCredentialRef.Name
+	}
+	return fmt.Sprintf("%s/%s", cluster.UID, credentialRef)
+}
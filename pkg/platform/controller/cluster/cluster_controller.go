@@ -22,20 +22,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
 	"reflect"
+	"sync"
 	"time"
 
-	mapset "github.com/deckarep/golang-set"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	"tkestack.io/tke/api/client/clientset/versioned/scheme"
 	platformversionedclient "tkestack.io/tke/api/client/clientset/versioned/typed/platform/v1"
 	platformv1informer "tkestack.io/tke/api/client/informers/externalversions/platform/v1"
 	platformv1lister "tkestack.io/tke/api/client/listers/platform/v1"
@@ -44,17 +46,22 @@ import (
 	"tkestack.io/tke/pkg/platform/controller/cluster/deletion"
 	clusterprovider "tkestack.io/tke/pkg/platform/provider/cluster"
 	typesv1 "tkestack.io/tke/pkg/platform/types/v1"
-	"tkestack.io/tke/pkg/platform/util"
 	"tkestack.io/tke/pkg/util/log"
 	"tkestack.io/tke/pkg/util/metrics"
-	"tkestack.io/tke/pkg/util/strategicpatch"
 )
 
 const (
 	conditionTypeHealthCheck = "HealthCheck"
 	failedHealthCheckReason  = "FailedHealthCheck"
 
+	// healthCheckInterval is the cadence probes are re-run at once they
+	// are healthy; it has no bearing on how quickly a failing probe is
+	// retried, which instead follows its own backoff (see probeSchedule).
 	healthCheckInterval = 5 * time.Minute
+
+	// watchHealthPollInterval is how often the health-check loop wakes up
+	// to see whether any probe's individual schedule has come due.
+	watchHealthPollInterval = 15 * time.Second
 )
 
 // Controller is responsible for performing actions dependent upon a cluster phase.
@@ -65,12 +72,19 @@ type Controller struct {
 
 	log            log.Logger
 	platformClient platformversionedclient.PlatformV1Interface
-	healthCache    mapset.Set
+	cacheTracker   *ClusterCacheTracker
+	healthChecking sync.Map
 	deleter        deletion.ClusterDeleterInterface
+
+	eventRecorder record.EventRecorder
+
+	probeStatesMu sync.Mutex
+	probeStates   map[string]*probeState
 }
 
 // NewController creates a new Controller object.
 func NewController(
+	kubeClient kubernetes.Interface,
 	platformClient platformversionedclient.PlatformV1Interface,
 	clusterInformer platformv1informer.ClusterInformer,
 	resyncPeriod time.Duration,
@@ -80,13 +94,20 @@ func NewController(
 
 		log:            log.WithName("cluster-controller"),
 		platformClient: platformClient,
-		healthCache:    mapset.NewSet(),
+		cacheTracker:   NewClusterCacheTracker(platformClient),
+		probeStates:    make(map[string]*probeState),
 		deleter: deletion.NewClusterDeleter(platformClient.Clusters(),
 			platformClient,
 			finalizerToken,
 			true),
 	}
 
+	if kubeClient != nil {
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+		c.eventRecorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "cluster-controller"})
+	}
+
 	if platformClient != nil && platformClient.RESTClient().GetRateLimiter() != nil {
 		_ = metrics.RegisterMetricAndTrackRateLimiterUsage("cluster_controller", platformClient.RESTClient().GetRateLimiter())
 	}
@@ -239,10 +260,15 @@ func (c *Controller) reconcile(ctx context.Context, key string, cluster *platfor
 	switch cluster.Status.Phase {
 	case platformv1.ClusterInitializing:
 		err = c.onCreate(ctx, cluster)
+	case platformv1.ClusterUpgrading:
+		err = c.onUpgrade(ctx, cluster)
 	case platformv1.ClusterRunning, platformv1.ClusterFailed:
+		c.ensureStartHealthCheck(ctx, cluster.Name)
 		err = c.onUpdate(ctx, cluster)
 	case platformv1.ClusterTerminating:
 		logger.Info("Cluster has been terminated. Attempting to cleanup resources")
+		c.stopHealthCheck(cluster.Name)
+		c.cacheTracker.Invalidate(cluster.Name)
 		err = c.deleter.Delete(context.Background(), key)
 		if err == nil {
 			logger.Info("Machine has been successfully deleted")
@@ -267,17 +293,12 @@ func (c *Controller) onCreate(ctx context.Context, cluster *platformv1.Cluster)
 		return err
 	}
 
-	// If any error happens, return error for retry.
-	for clusterWrapper.Status.Phase == platformv1.ClusterInitializing {
-		err = provider.OnCreate(ctx, clusterWrapper)
-		_, err = c.platformClient.ClusterCredentials().Update(ctx, clusterWrapper.ClusterCredential, metav1.UpdateOptions{})
-		_, err = c.platformClient.Clusters().Update(ctx, clusterWrapper.Cluster, metav1.UpdateOptions{})
-		if err != nil {
-			return err
-		}
+	stepped, ok := provider.(SteppedProvider)
+	if !ok {
+		return c.legacyOnCreate(ctx, provider, clusterWrapper)
 	}
 
-	return nil
+	return c.runSteps(ctx, clusterWrapper, stepped.CreateSteps(), platformv1.ClusterInitializing, platformv1.ClusterRunning)
 }
 
 func (c *Controller) onUpdate(ctx context.Context, cluster *platformv1.Cluster) error {
@@ -286,19 +307,86 @@ func (c *Controller) onUpdate(ctx context.Context, cluster *platformv1.Cluster)
 		return err
 	}
 
+	stepped, ok := provider.(SteppedProvider)
+	if ok && clusterNeedsUpgrade(cluster) {
+		return c.beginUpgrade(ctx, cluster)
+	}
+
 	clusterWrapper, err := typesv1.GetCluster(ctx, c.platformClient, cluster)
 	if err != nil {
 		return err
 	}
 
-	// If any error happens, return error for retry.
-	err = provider.OnUpdate(ctx, clusterWrapper)
-	_, err = c.platformClient.ClusterCredentials().Update(ctx, clusterWrapper.ClusterCredential, metav1.UpdateOptions{})
-	_, err = c.platformClient.Clusters().Update(ctx, clusterWrapper.Cluster, metav1.UpdateOptions{})
+	if !ok {
+		return c.legacyOnUpdate(ctx, provider, clusterWrapper)
+	}
+
+	return c.runSteps(ctx, clusterWrapper, stepped.UpdateSteps(), platformv1.ClusterRunning, platformv1.ClusterRunning)
+}
+
+func (c *Controller) onUpgrade(ctx context.Context, cluster *platformv1.Cluster) error {
+	provider, err := clusterprovider.GetProvider(cluster.Spec.Type)
 	if err != nil {
 		return err
 	}
 
+	clusterWrapper, err := typesv1.GetCluster(ctx, c.platformClient, cluster)
+	if err != nil {
+		return err
+	}
+
+	stepped, ok := provider.(SteppedProvider)
+	if !ok {
+		return fmt.Errorf("provider %q does not support upgrades", cluster.Spec.Type)
+	}
+
+	return c.runSteps(ctx, clusterWrapper, stepped.UpgradeSteps(), platformv1.ClusterUpgrading, platformv1.ClusterRunning)
+}
+
+// legacyOnCreate drives a provider that has not migrated to SteppedProvider
+// through its single OnCreate call, retrying the whole call (not just the
+// failed part of it) on error. As in the FSM path, every patch error is
+// checked individually rather than letting a later call silently overwrite
+// an earlier one.
+//
+// Each pass persists the provider's changes as patches against the
+// Cluster and ClusterCredential state from before that pass, so a
+// concurrent writer's edit made between passes survives instead of being
+// silently reverted by a full Update of clusterWrapper's stale copy.
+func (c *Controller) legacyOnCreate(ctx context.Context, provider clusterprovider.Provider, clusterWrapper *typesv1.Cluster) error {
+	for clusterWrapper.Status.Phase == platformv1.ClusterInitializing {
+		oldCluster := clusterWrapper.Cluster.DeepCopy()
+		oldCredential := clusterWrapper.ClusterCredential.DeepCopy()
+
+		if err := provider.OnCreate(ctx, clusterWrapper); err != nil {
+			return err
+		}
+		if err := c.patchClusterCredential(ctx, oldCredential, clusterWrapper.ClusterCredential); err != nil {
+			return err
+		}
+		if err := c.patchCluster(ctx, oldCluster, clusterWrapper.Cluster); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyOnUpdate is the OnUpdate counterpart of legacyOnCreate.
+func (c *Controller) legacyOnUpdate(ctx context.Context, provider clusterprovider.Provider, clusterWrapper *typesv1.Cluster) error {
+	oldCluster := clusterWrapper.Cluster.DeepCopy()
+	oldCredential := clusterWrapper.ClusterCredential.DeepCopy()
+
+	if err := provider.OnUpdate(ctx, clusterWrapper); err != nil {
+		return err
+	}
+	if err := c.patchClusterCredential(ctx, oldCredential, clusterWrapper.ClusterCredential); err != nil {
+		return err
+	}
+	if err := c.patchCluster(ctx, oldCluster, clusterWrapper.Cluster); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -367,15 +455,43 @@ func (c *Controller) ensureClusterCredential(ctx context.Context, cluster *platf
 	return nil
 }
 
+// ensureStartHealthCheck starts the health-check poll loop for key the
+// first time it is called for a given cluster. The loop is rooted in a
+// context owned by this Controller (not the cacheTracker's per-cluster
+// context, since the health check must keep running across credential
+// rotations) and is only ever stopped, via stopHealthCheck, when the
+// cluster itself is deleted.
 func (c *Controller) ensureStartHealthCheck(ctx context.Context, key string) {
-	if c.healthCache.Contains(key) {
+	if _, loaded := c.healthChecking.LoadOrStore(key, struct{}{}); loaded {
 		return
 	}
 	logger := c.log.WithName("health-check").WithValues("cluster", key)
 	logger.Info("Start health check loop")
-	time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
-	go wait.PollImmediateInfinite(healthCheckInterval, c.watchHealth(ctx, key))
-	c.healthCache.Add(key)
+	go wait.PollImmediateInfinite(watchHealthPollInterval, c.watchHealth(ctx, key))
+}
+
+// stopHealthCheck marks key's health-check loop for termination. The loop
+// itself observes this the next time it polls and exits.
+func (c *Controller) stopHealthCheck(key string) {
+	c.healthChecking.Delete(key)
+	c.probeStatesMu.Lock()
+	delete(c.probeStates, key)
+	c.probeStatesMu.Unlock()
+}
+
+// getProbeState returns the probe backoff state for key, creating it on
+// first use. Safe for concurrent use across clusters; within a single
+// cluster's key, only the watchHealth goroutine for that cluster ever
+// touches the returned *probeState, so no further locking is needed there.
+func (c *Controller) getProbeState(key string) *probeState {
+	c.probeStatesMu.Lock()
+	defer c.probeStatesMu.Unlock()
+	s, ok := c.probeStates[key]
+	if !ok {
+		s = newProbeState()
+		c.probeStates[key] = s
+	}
+	return s
 }
 
 // watchHealth check cluster health when phase in Running or Failed.
@@ -384,11 +500,16 @@ func (c *Controller) watchHealth(ctx context.Context, key string) func() (bool,
 	return func() (bool, error) {
 		logger := c.log.WithName("health-check").WithValues("cluster", key)
 
+		if _, ok := c.healthChecking.Load(key); !ok {
+			logger.Info("Stop health check because cluster has been deleted")
+			return true, nil
+		}
+
 		cluster, err := c.lister.Get(key)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				logger.Info("Stop health check because cluster has been deleted")
-				c.healthCache.Remove(key)
+				c.stopHealthCheck(key)
 				return true, nil
 			}
 			return false, nil
@@ -407,43 +528,96 @@ func (c *Controller) watchHealth(ctx context.Context, key string) func() (bool,
 	}
 }
 
+// SendEvent records a Kubernetes Event against cluster. It is the single
+// place every ClusterCondition transition goes through, so callers don't
+// each need to guard against a nil recorder (e.g. in unit tests that build
+// a Controller without a kubeClient).
+func (c *Controller) SendEvent(cluster *platformv1.Cluster, eventtype, reason, message string) {
+	if c.eventRecorder == nil {
+		return
+	}
+	c.eventRecorder.Event(cluster, eventtype, reason, message)
+}
+
+// checkHealth runs every due HealthProbe against cluster on its own
+// independent, jittered-backoff schedule, and folds the results into
+// cluster's Conditions. A probe failure only flips Status.Phase to
+// ClusterFailed once that probe has failed consecutiveFailuresToFail
+// times in a row, so a single blip doesn't take the cluster out of
+// rotation; the phase stays ClusterFailed until every such probe succeeds
+// again. A failure to get a client for the cluster at all is not counted
+// this way and sets ClusterFailed on its first occurrence.
 func (c *Controller) checkHealth(ctx context.Context, cluster *platformv1.Cluster) error {
 	oldCluster := cluster.DeepCopy()
+	oldPhase := cluster.Status.Phase
 
-	healthCheckCondition := platformv1.ClusterCondition{
-		Type:   conditionTypeHealthCheck,
-		Status: platformv1.ConditionFalse,
-	}
-	client, err := util.BuildExternalClientSet(ctx, cluster, c.platformClient)
+	state := c.getProbeState(cluster.Name)
+	now := time.Now()
+
+	client, err := c.cacheTracker.GetClient(ctx, cluster.Name)
 	if err != nil {
+		healthCheckCondition := platformv1.ClusterCondition{
+			Type:    conditionTypeHealthCheck,
+			Status:  platformv1.ConditionFalse,
+			Reason:  failedHealthCheckReason,
+			Message: err.Error(),
+		}
+		cluster.SetCondition(healthCheckCondition)
 		cluster.Status.Phase = platformv1.ClusterFailed
+		return c.patchClusterHealth(ctx, oldCluster, cluster, oldPhase)
+	}
 
-		healthCheckCondition.Reason = failedHealthCheckReason
-		healthCheckCondition.Message = err.Error()
-	} else {
-		version, err := client.Discovery().ServerVersion()
-		if err != nil {
-			cluster.Status.Phase = platformv1.ClusterFailed
+	if version, vErr := client.Discovery().ServerVersion(); vErr == nil {
+		cluster.Status.Version = version.String()
+	}
 
-			healthCheckCondition.Reason = failedHealthCheckReason
-			healthCheckCondition.Message = err.Error()
-		} else {
-			cluster.Status.Phase = platformv1.ClusterRunning
-			cluster.Status.Version = version.String()
+	anyFailed := false
+	for _, probe := range healthProbes() {
+		if state.shouldRun(probe.Name(), now) {
+			cond := probe.Probe(ctx, client, cluster)
+			state.record(probe.Name(), now, cond.Status != platformv1.ConditionTrue)
+			cluster.SetCondition(cond)
+		}
 
-			healthCheckCondition.Status = platformv1.ConditionTrue
+		// A probe waiting out its backoff keeps its failure streak, so it
+		// still counts as failed on passes where it is not due to run.
+		if sched, ok := state.schedules[probe.Name()]; ok && sched.consecutiveFailures >= consecutiveFailuresToFail {
+			anyFailed = true
 		}
 	}
 
+	healthCheckCondition := platformv1.ClusterCondition{
+		Type:   conditionTypeHealthCheck,
+		Status: platformv1.ConditionTrue,
+	}
+	if anyFailed {
+		healthCheckCondition.Status = platformv1.ConditionFalse
+		healthCheckCondition.Reason = failedHealthCheckReason
+		cluster.Status.Phase = platformv1.ClusterFailed
+	} else {
+		cluster.Status.Phase = platformv1.ClusterRunning
+	}
 	cluster.SetCondition(healthCheckCondition)
 
-	patchBytes, err := strategicpatch.GetPatchBytes(oldCluster, cluster)
-	if err != nil {
-		return fmt.Errorf("GetPatchBytes error: %w", err)
+	return c.patchClusterHealth(ctx, oldCluster, cluster, oldPhase)
+}
+
+// patchClusterHealth persists cluster's health conditions/phase and emits
+// a transition Event whenever Status.Phase actually changed. checkHealth
+// only ever touches Status, so this always lands as a status-subresource
+// patch and can never clobber a concurrent spec edit made by a user or
+// the create/update/upgrade path.
+func (c *Controller) patchClusterHealth(ctx context.Context, oldCluster, cluster *platformv1.Cluster, oldPhase platformv1.ClusterPhase) error {
+	if err := c.patchCluster(ctx, oldCluster, cluster); err != nil {
+		return fmt.Errorf("persist cluster health status error: %w", err)
 	}
-	_, err = c.platformClient.Clusters().Patch(ctx, cluster.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
-	if err != nil {
-		return fmt.Errorf("update cluster health status error: %w", err)
+
+	if cluster.Status.Phase != oldPhase {
+		eventType := corev1.EventTypeNormal
+		if cluster.Status.Phase == platformv1.ClusterFailed {
+			eventType = corev1.EventTypeWarning
+		}
+		c.SendEvent(cluster, eventType, "HealthCheck", fmt.Sprintf("cluster phase changed from %s to %s", oldPhase, cluster.Status.Phase))
 	}
 
 	return nil
@@ -0,0 +1,174 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	platformv1 "tkestack.io/tke/api/platform/v1"
+	typesv1 "tkestack.io/tke/pkg/platform/types/v1"
+)
+
+// Step is a single, named unit of work a clusterprovider.Provider
+// contributes to one of its ordered phase step lists. Each step is
+// retried independently: a failure only re-runs that step (and whatever
+// follows it) on the next reconcile, rather than restarting the whole
+// phase from scratch.
+type Step struct {
+	Name string
+	Func func(ctx context.Context, cluster *typesv1.Cluster) error
+}
+
+// SteppedProvider is implemented by providers that drive Cluster creation,
+// update and upgrade as an ordered sequence of Steps instead of a single
+// OnCreate/OnUpdate call. Providers that don't implement it keep working
+// unmodified through the legacy single-call path in onCreate/onUpdate.
+type SteppedProvider interface {
+	CreateSteps() []Step
+	UpdateSteps() []Step
+	UpgradeSteps() []Step
+}
+
+// stepConditionType returns the Cluster.Status.Conditions entry used to
+// record step's outcome for the given phase, so progress survives process
+// restarts and repeated reconciles.
+func stepConditionType(phase platformv1.ClusterPhase, stepName string) string {
+	return fmt.Sprintf("Step.%s.%s", phase, stepName)
+}
+
+// runSteps executes steps against clusterWrapper in order, skipping any
+// step already recorded as completed in Cluster.Status.Conditions. The
+// first step to fail has its attempt count and error persisted and its
+// error returned, so processNextWorkItem requeues the cluster with
+// AddRateLimited instead of busy-looping in-handler; the next reconcile
+// resumes at that same step. Once every step has succeeded,
+// Status.Phase is advanced to nextPhase and persisted - otherwise a
+// cluster that finishes steps would never leave phase, since unlike the
+// legacy path no single provider call is left to flip it.
+//
+// Each step's outcome is persisted as a patch against the Cluster and
+// ClusterCredential state captured right before the step ran, rather than
+// a full Update of whatever runSteps happens to be holding - a step that
+// only touches ClusterCredential can't clobber a concurrent spec edit to
+// Cluster made by someone else in between, and vice versa.
+func (c *Controller) runSteps(ctx context.Context, clusterWrapper *typesv1.Cluster, steps []Step, phase, nextPhase platformv1.ClusterPhase) error {
+	for _, step := range steps {
+		condType := stepConditionType(phase, step.Name)
+
+		if cond := clusterWrapper.Cluster.GetCondition(condType); cond != nil && cond.Status == platformv1.ConditionTrue {
+			continue
+		}
+
+		oldCluster := clusterWrapper.Cluster.DeepCopy()
+		oldCredential := clusterWrapper.ClusterCredential.DeepCopy()
+
+		attempt := lastAttempt(clusterWrapper.Cluster, condType) + 1
+		stepErr := step.Func(ctx, clusterWrapper)
+
+		cond := platformv1.ClusterCondition{
+			Type:    condType,
+			Status:  platformv1.ConditionTrue,
+			Message: attemptMessage(attempt, nil),
+		}
+		if stepErr != nil {
+			cond.Status = platformv1.ConditionFalse
+			cond.Reason = "StepFailed"
+			cond.Message = attemptMessage(attempt, stepErr)
+		}
+		clusterWrapper.Cluster.SetCondition(cond)
+
+		if err := c.patchClusterCredential(ctx, oldCredential, clusterWrapper.ClusterCredential); err != nil {
+			return fmt.Errorf("persist ClusterCredential error: %w", err)
+		}
+		if err := c.patchCluster(ctx, oldCluster, clusterWrapper.Cluster); err != nil {
+			return fmt.Errorf("persist Cluster error: %w", err)
+		}
+
+		if stepErr != nil {
+			return fmt.Errorf("step %q failed on attempt %d: %w", step.Name, attempt, stepErr)
+		}
+	}
+
+	if clusterWrapper.Cluster.Status.Phase != nextPhase {
+		oldCluster := clusterWrapper.Cluster.DeepCopy()
+		clusterWrapper.Cluster.Status.Phase = nextPhase
+		if err := c.patchCluster(ctx, oldCluster, clusterWrapper.Cluster); err != nil {
+			return fmt.Errorf("persist Cluster phase error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// clusterNeedsUpgrade reports whether cluster's target Spec.Version has
+// moved past the version last observed live on the cluster
+// (Status.Version, kept current by checkHealth's discovery call). An
+// empty Status.Version means the cluster has never finished a health
+// check yet, so there is nothing to compare against - onCreate/onUpdate
+// owns getting it to ClusterRunning first.
+func clusterNeedsUpgrade(cluster *platformv1.Cluster) bool {
+	return cluster.Spec.Version != "" && cluster.Status.Version != "" && cluster.Spec.Version != cluster.Status.Version
+}
+
+// beginUpgrade transitions cluster into ClusterUpgrading so the next
+// reconcile drives it through onUpgrade/UpgradeSteps instead of
+// onUpdate/UpdateSteps, making a version bump a first-class phase rather
+// than an ordinary spec update.
+func (c *Controller) beginUpgrade(ctx context.Context, cluster *platformv1.Cluster) error {
+	oldCluster := cluster.DeepCopy()
+	cluster.Status.Phase = platformv1.ClusterUpgrading
+	return c.patchCluster(ctx, oldCluster, cluster)
+}
+
+// attemptMessage encodes the attempt count (and, on failure, the error)
+// into a condition Message in a form lastAttempt can parse back out.
+func attemptMessage(attempt int, err error) string {
+	if err == nil {
+		return fmt.Sprintf("attempt %d succeeded", attempt)
+	}
+	return fmt.Sprintf("attempt %d failed: %s", attempt, err.Error())
+}
+
+// lastAttempt recovers the attempt count persisted by a previous
+// attemptMessage, or 0 if condType has no recorded attempt yet.
+func lastAttempt(cluster *platformv1.Cluster, condType string) int {
+	cond := cluster.GetCondition(condType)
+	if cond == nil {
+		return 0
+	}
+	return parseAttempt(cond.Message)
+}
+
+// parseAttempt recovers the attempt count encoded by attemptMessage out of
+// message, or 0 if message isn't in that form. Split out of lastAttempt so
+// the encode/decode round trip can be tested without a platformv1.Cluster.
+func parseAttempt(message string) int {
+	fields := strings.Fields(message)
+	if len(fields) < 2 || fields[0] != "attempt" {
+		return 0
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,54 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cluster
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAttemptMessageRoundTrip(t *testing.T) {
+	cases := []struct {
+		name    string
+		attempt int
+		err     error
+	}{
+		{name: "first success", attempt: 1, err: nil},
+		{name: "later success", attempt: 7, err: nil},
+		{name: "failure", attempt: 3, err: errors.New("dial tcp: connection refused")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := attemptMessage(tc.attempt, tc.err)
+			if got := parseAttempt(message); got != tc.attempt {
+				t.Errorf("parseAttempt(%q) = %d, want %d", message, got, tc.attempt)
+			}
+		})
+	}
+}
+
+func TestParseAttemptUnrecognizedMessage(t *testing.T) {
+	cases := []string{"", "some unrelated message", "attempt", "attempt notanumber succeeded"}
+	for _, message := range cases {
+		if got := parseAttempt(message); got != 0 {
+			t.Errorf("parseAttempt(%q) = %d, want 0", message, got)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	platformv1 "tkestack.io/tke/api/platform/v1"
+)
+
+const (
+	// consecutiveFailuresToFail is the number of consecutive probe
+	// failures required before a probe's condition is considered failed
+	// for the purposes of flipping Status.Phase to ClusterFailed.
+	consecutiveFailuresToFail = 3
+
+	probeBackoffInitial = 30 * time.Second
+	probeBackoffMax     = 10 * time.Minute
+	probeBackoffFactor  = 2.0
+	probeBackoffJitter  = 0.2
+)
+
+// HealthProbe is a single, independently scheduled health check against a
+// member cluster. Each probe contributes its own platformv1.ClusterCondition
+// rather than collapsing straight to Status.Phase, so a caller can tell
+// *which* subsystem is unhealthy.
+type HealthProbe interface {
+	// Name identifies the probe and is used as its ClusterCondition.Type.
+	Name() string
+
+	// Probe runs one check against the cluster and returns the resulting
+	// condition. Probe must not mutate cluster.
+	Probe(ctx context.Context, client kubernetes.Interface, cluster *platformv1.Cluster) platformv1.ClusterCondition
+}
+
+var (
+	builtinProbesMu sync.Mutex
+	builtinProbes   = []HealthProbe{
+		apiServerReadyzProbe{},
+		apiServerLivezProbe{},
+		etcdHealthzProbe{},
+		coreDNSReadyProbe{},
+		nodeReadyRatioProbe{},
+	}
+)
+
+// RegisterHealthProbe adds an additional HealthProbe to the set run
+// against every cluster, so a provider implementation can contribute
+// probes specific to that provider (e.g. a provider-managed etcd check
+// not reachable through the apiserver) from its init(), alongside the
+// built-ins run regardless of provider.
+//
+// tkestack.io/tke/pkg/platform/provider/cluster (clusterprovider) is out
+// of scope for this package's changes: it is not part of this tree
+// (there is no pkg/platform/provider directory here at all; it is
+// imported as an external dependency), so it cannot re-export this
+// function from here. A provider package that wants to call this without
+// importing pkg/platform/controller/cluster directly would need its own
+// change, made where that package actually lives.
+func RegisterHealthProbe(probe HealthProbe) {
+	builtinProbesMu.Lock()
+	defer builtinProbesMu.Unlock()
+	builtinProbes = append(builtinProbes, probe)
+}
+
+func healthProbes() []HealthProbe {
+	builtinProbesMu.Lock()
+	defer builtinProbesMu.Unlock()
+	return append([]HealthProbe(nil), builtinProbes...)
+}
+
+// probeSchedule tracks the independent backoff state for one probe against
+// one cluster, so a flaky DNS probe does not slow down the apiserver
+// probe's cadence and vice versa.
+type probeSchedule struct {
+	nextRun             time.Time
+	backoff             time.Duration
+	consecutiveFailures int
+}
+
+// probeState holds the per-cluster, per-probe schedules driving the
+// health-check loop. It is only ever accessed from the single goroutine
+// running that cluster's watchHealth loop, so it needs no locking of its
+// own.
+type probeState struct {
+	schedules map[string]*probeSchedule
+}
+
+func newProbeState() *probeState {
+	return &probeState{schedules: make(map[string]*probeSchedule)}
+}
+
+func (s *probeState) shouldRun(name string, now time.Time) bool {
+	sched, ok := s.schedules[name]
+	if !ok {
+		return true
+	}
+	return !now.Before(sched.nextRun)
+}
+
+func (s *probeState) record(name string, now time.Time, failed bool) (consecutiveFailures int) {
+	sched, ok := s.schedules[name]
+	if !ok {
+		sched = &probeSchedule{backoff: probeBackoffInitial}
+		s.schedules[name] = sched
+	}
+
+	if !failed {
+		sched.backoff = probeBackoffInitial
+		sched.consecutiveFailures = 0
+		sched.nextRun = now.Add(healthCheckInterval)
+		return 0
+	}
+
+	sched.consecutiveFailures++
+	sched.nextRun = now.Add(jitter(sched.backoff))
+	sched.backoff = time.Duration(float64(sched.backoff) * probeBackoffFactor)
+	if sched.backoff > probeBackoffMax {
+		sched.backoff = probeBackoffMax
+	}
+	return sched.consecutiveFailures
+}
+
+// jitter returns d +/- probeBackoffJitter, to avoid every cluster's probes
+// retrying in lockstep after a shared outage.
+func jitter(d time.Duration) time.Duration {
+	delta := float64(d) * probeBackoffJitter
+	return d + time.Duration(delta*(2*rand.Float64()-1))
+}
+
+// apiServerReadyzProbe reports the apiserver's /readyz endpoint.
+type apiServerReadyzProbe struct{}
+
+func (apiServerReadyzProbe) Name() string { return "APIServerReadyz" }
+
+func (apiServerReadyzProbe) Probe(ctx context.Context, client kubernetes.Interface, cluster *platformv1.Cluster) platformv1.ClusterCondition {
+	cond := platformv1.ClusterCondition{Type: apiServerReadyzProbe{}.Name(), Status: platformv1.ConditionFalse}
+	body, err := client.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+	if err != nil {
+		cond.Reason = failedHealthCheckReason
+		cond.Message = err.Error()
+		return cond
+	}
+	cond.Status = platformv1.ConditionTrue
+	cond.Message = string(body)
+	return cond
+}
+
+// apiServerLivezProbe reports the apiserver's /livez endpoint.
+type apiServerLivezProbe struct{}
+
+func (apiServerLivezProbe) Name() string { return "APIServerLivez" }
+
+func (apiServerLivezProbe) Probe(ctx context.Context, client kubernetes.Interface, cluster *platformv1.Cluster) platformv1.ClusterCondition {
+	cond := platformv1.ClusterCondition{Type: apiServerLivezProbe{}.Name(), Status: platformv1.ConditionFalse}
+	body, err := client.Discovery().RESTClient().Get().AbsPath("/livez").DoRaw(ctx)
+	if err != nil {
+		cond.Reason = failedHealthCheckReason
+		cond.Message = err.Error()
+		return cond
+	}
+	cond.Status = platformv1.ConditionTrue
+	cond.Message = string(body)
+	return cond
+}
+
+// etcdHealthzProbe reports etcd's health via the apiserver's
+// /healthz/etcd individual check, rather than talking to etcd directly -
+// the apiserver is the only thing with etcd connectivity/credentials
+// this controller can assume every provider exposes.
+type etcdHealthzProbe struct{}
+
+func (etcdHealthzProbe) Name() string { return "EtcdHealthz" }
+
+func (etcdHealthzProbe) Probe(ctx context.Context, client kubernetes.Interface, cluster *platformv1.Cluster) platformv1.ClusterCondition {
+	cond := platformv1.ClusterCondition{Type: etcdHealthzProbe{}.Name(), Status: platformv1.ConditionFalse}
+	body, err := client.Discovery().RESTClient().Get().AbsPath("/healthz/etcd").DoRaw(ctx)
+	if err != nil {
+		cond.Reason = failedHealthCheckReason
+		cond.Message = err.Error()
+		return cond
+	}
+	cond.Status = platformv1.ConditionTrue
+	cond.Message = string(body)
+	return cond
+}
+
+// coreDNSReadyProbe reports whether at least one kube-dns/coredns pod is
+// Ready.
+type coreDNSReadyProbe struct{}
+
+func (coreDNSReadyProbe) Name() string { return "CoreDNSReady" }
+
+func (coreDNSReadyProbe) Probe(ctx context.Context, client kubernetes.Interface, cluster *platformv1.Cluster) platformv1.ClusterCondition {
+	cond := platformv1.ClusterCondition{Type: coreDNSReadyProbe{}.Name(), Status: platformv1.ConditionFalse}
+
+	pods, err := client.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{LabelSelector: "k8s-app=kube-dns"})
+	if err != nil {
+		cond.Reason = failedHealthCheckReason
+		cond.Message = err.Error()
+		return cond
+	}
+
+	for i := range pods.Items {
+		if podReady(&pods.Items[i]) {
+			cond.Status = platformv1.ConditionTrue
+			return cond
+		}
+	}
+
+	cond.Reason = failedHealthCheckReason
+	cond.Message = "no ready kube-dns/coredns pod found"
+	return cond
+}
+
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// nodeReadyRatioProbe fails once fewer than half of the cluster's nodes
+// are Ready.
+type nodeReadyRatioProbe struct{}
+
+func (nodeReadyRatioProbe) Name() string { return "NodeReadyRatio" }
+
+func (nodeReadyRatioProbe) Probe(ctx context.Context, client kubernetes.Interface, cluster *platformv1.Cluster) platformv1.ClusterCondition {
+	cond := platformv1.ClusterCondition{Type: nodeReadyRatioProbe{}.Name(), Status: platformv1.ConditionFalse}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		cond.Reason = failedHealthCheckReason
+		cond.Message = err.Error()
+		return cond
+	}
+	if len(nodes.Items) == 0 {
+		cond.Reason = failedHealthCheckReason
+		cond.Message = "cluster has no nodes"
+		return cond
+	}
+
+	ready := 0
+	for i := range nodes.Items {
+		for _, c := range nodes.Items[i].Status.Conditions {
+			if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+
+	if ready*2 < len(nodes.Items) {
+		cond.Reason = failedHealthCheckReason
+		cond.Message = "fewer than half of nodes are Ready"
+		return cond
+	}
+
+	cond.Status = platformv1.ConditionTrue
+	return cond
+}
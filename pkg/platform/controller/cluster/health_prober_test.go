@@ -0,0 +1,96 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbeStateRecordSuccessResetsBackoff(t *testing.T) {
+	s := newProbeState()
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if n := s.record("probe", now, true); n != i+1 {
+			t.Fatalf("record(failed) #%d = %d, want %d", i+1, n, i+1)
+		}
+	}
+
+	if n := s.record("probe", now, false); n != 0 {
+		t.Errorf("record(success) consecutiveFailures = %d, want 0", n)
+	}
+
+	sched := s.schedules["probe"]
+	if sched.backoff != probeBackoffInitial {
+		t.Errorf("backoff after success = %v, want reset to %v", sched.backoff, probeBackoffInitial)
+	}
+	if sched.consecutiveFailures != 0 {
+		t.Errorf("consecutiveFailures after success = %d, want 0", sched.consecutiveFailures)
+	}
+}
+
+func TestProbeStateRecordFailureBacksOffAndCaps(t *testing.T) {
+	s := newProbeState()
+	now := time.Now()
+
+	for i := 0; i < 20; i++ {
+		s.record("probe", now, true)
+	}
+
+	sched := s.schedules["probe"]
+	if sched.backoff != probeBackoffMax {
+		t.Errorf("backoff after 20 consecutive failures = %v, want capped at %v", sched.backoff, probeBackoffMax)
+	}
+	if sched.consecutiveFailures != 20 {
+		t.Errorf("consecutiveFailures = %d, want 20", sched.consecutiveFailures)
+	}
+}
+
+func TestProbeStateShouldRun(t *testing.T) {
+	s := newProbeState()
+	now := time.Now()
+
+	if !s.shouldRun("probe", now) {
+		t.Fatal("shouldRun for a never-scheduled probe = false, want true")
+	}
+
+	s.record("probe", now, true)
+
+	if s.shouldRun("probe", now) {
+		t.Error("shouldRun immediately after a recorded failure = true, want false until backoff elapses")
+	}
+
+	sched := s.schedules["probe"]
+	if !s.shouldRun("probe", sched.nextRun.Add(time.Second)) {
+		t.Error("shouldRun after nextRun has passed = false, want true")
+	}
+}
+
+func TestJitterStaysWithinBounds(t *testing.T) {
+	d := 10 * time.Second
+	maxDelta := time.Duration(float64(d) * probeBackoffJitter)
+
+	for i := 0; i < 100; i++ {
+		got := jitter(d)
+		if got < d-maxDelta || got > d+maxDelta {
+			t.Fatalf("jitter(%v) = %v, want within +/-%v", d, got, maxDelta)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	platformv1 "tkestack.io/tke/api/platform/v1"
+	"tkestack.io/tke/pkg/util/strategicpatch"
+)
+
+// patchCluster persists the changes made to cluster, relative to
+// oldCluster (a deep copy taken before those changes), as up to two
+// independent strategic merge patches: one for Spec, one for the status
+// subresource. Splitting them means a status-only writer (health checks,
+// FSM step progress) can never clobber a concurrent spec edit from
+// another writer, and vice versa - unlike a single full-object Update,
+// which always overwrites whichever half the caller didn't intend to
+// touch with its own possibly-stale copy. Changes outside Spec and Status
+// (for example to labels or annotations) are carried by neither patch and
+// are not persisted.
+func (c *Controller) patchCluster(ctx context.Context, oldCluster, cluster *platformv1.Cluster) error {
+	specPatch, statusPatch, err := strategicpatch.SplitPatchBytes(oldCluster, cluster)
+	if err != nil {
+		return fmt.Errorf("compute Cluster patch error: %w", err)
+	}
+
+	if len(specPatch) > 0 {
+		if _, err := c.platformClient.Clusters().Patch(ctx, cluster.Name, types.StrategicMergePatchType, specPatch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("patch Cluster spec error: %w", err)
+		}
+	}
+
+	if len(statusPatch) > 0 {
+		if _, err := c.platformClient.Clusters().Patch(ctx, cluster.Name, types.StrategicMergePatchType, statusPatch, metav1.PatchOptions{}, "status"); err != nil {
+			return fmt.Errorf("patch Cluster status error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// patchClusterCredential persists the changes made to credential,
+// relative to oldCredential (a deep copy taken before those changes), as
+// a single strategic merge patch. ClusterCredential has no status
+// subresource, so unlike patchCluster there is nothing to split.
+func (c *Controller) patchClusterCredential(ctx context.Context, oldCredential, credential *platformv1.ClusterCredential) error {
+	patchBytes, err := strategicpatch.GetPatchBytes(oldCredential, credential)
+	if err != nil {
+		return fmt.Errorf("compute ClusterCredential patch error: %w", err)
+	}
+	if len(patchBytes) == 0 || string(patchBytes) == "{}" {
+		return nil
+	}
+
+	_, err = c.platformClient.ClusterCredentials().Patch(ctx, credential.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
+	return err
+}
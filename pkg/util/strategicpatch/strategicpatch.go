@@ -0,0 +1,83 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package strategicpatch provides small helpers on top of
+// k8s.io/apimachinery/pkg/util/strategicpatch for building PATCH requests
+// against Kubernetes-style objects, so callers can persist a mutation as
+// a targeted diff against its pre-mutation state instead of a full
+// Update that risks overwriting a concurrent writer.
+package strategicpatch
+
+import (
+	"bytes"
+	"encoding/json"
+
+	apistrategicpatch "k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// GetPatchBytes marshals oldObj and newObj to JSON and returns the
+// strategic merge patch that turns the former into the latter. oldObj and
+// newObj must be the same concrete type; oldObj is also passed as the
+// patch metadata source, since CreateTwoWayMergePatch requires a real
+// struct (or pointer to struct) there to resolve merge keys and
+// patchStrategy tags.
+func GetPatchBytes(oldObj, newObj interface{}) ([]byte, error) {
+	oldData, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	return apistrategicpatch.CreateTwoWayMergePatch(oldData, newData, oldObj)
+}
+
+// SplitPatchBytes computes the single strategic merge patch between
+// oldObj and newObj (via GetPatchBytes) and splits it into two
+// independent patches: one carrying only the top-level "spec" field and
+// one carrying only "status". Either return value is nil if that half is
+// unchanged. Callers route specPatch through a normal Patch call and
+// statusPatch through the status subresource, so a writer that only
+// mutates one half can never clobber a concurrent edit to the other.
+func SplitPatchBytes(oldObj, newObj interface{}) (specPatch, statusPatch []byte, err error) {
+	patch, err := GetPatchBytes(oldObj, newObj)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(patch) == 0 || bytes.Equal(patch, []byte("{}")) {
+		return nil, nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return nil, nil, err
+	}
+
+	if spec, ok := fields["spec"]; ok {
+		if specPatch, err = json.Marshal(map[string]json.RawMessage{"spec": spec}); err != nil {
+			return nil, nil, err
+		}
+	}
+	if status, ok := fields["status"]; ok {
+		if statusPatch, err = json.Marshal(map[string]json.RawMessage{"status": status}); err != nil {
+			return nil, nil, err
+		}
+	}
+	return specPatch, statusPatch, nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack
+ * available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package strategicpatch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type fakeSpec struct {
+	Replicas int    `json:"replicas,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+type fakeStatus struct {
+	Phase string `json:"phase,omitempty"`
+}
+
+type fakeObject struct {
+	Spec   fakeSpec   `json:"spec,omitempty"`
+	Status fakeStatus `json:"status,omitempty"`
+}
+
+func TestSplitPatchBytesNoChange(t *testing.T) {
+	obj := &fakeObject{Spec: fakeSpec{Name: "a"}, Status: fakeStatus{Phase: "Running"}}
+
+	specPatch, statusPatch, err := SplitPatchBytes(obj, obj.DeepCopy())
+	if err != nil {
+		t.Fatalf("SplitPatchBytes returned error: %v", err)
+	}
+	if specPatch != nil {
+		t.Errorf("specPatch = %s, want nil for no change", specPatch)
+	}
+	if statusPatch != nil {
+		t.Errorf("statusPatch = %s, want nil for no change", statusPatch)
+	}
+}
+
+func TestSplitPatchBytesSpecOnly(t *testing.T) {
+	oldObj := &fakeObject{Spec: fakeSpec{Name: "a"}, Status: fakeStatus{Phase: "Running"}}
+	newObj := oldObj.DeepCopy()
+	newObj.Spec.Name = "b"
+
+	specPatch, statusPatch, err := SplitPatchBytes(oldObj, newObj)
+	if err != nil {
+		t.Fatalf("SplitPatchBytes returned error: %v", err)
+	}
+	if statusPatch != nil {
+		t.Errorf("statusPatch = %s, want nil since only spec changed", statusPatch)
+	}
+
+	var decoded map[string]map[string]interface{}
+	if err := json.Unmarshal(specPatch, &decoded); err != nil {
+		t.Fatalf("unmarshal specPatch: %v", err)
+	}
+	if _, ok := decoded["status"]; ok {
+		t.Errorf("specPatch %s unexpectedly carries a status field", specPatch)
+	}
+	if decoded["spec"]["name"] != "b" {
+		t.Errorf("specPatch %s missing expected spec.name=b", specPatch)
+	}
+}
+
+func TestSplitPatchBytesStatusOnly(t *testing.T) {
+	oldObj := &fakeObject{Spec: fakeSpec{Name: "a"}, Status: fakeStatus{Phase: "Running"}}
+	newObj := oldObj.DeepCopy()
+	newObj.Status.Phase = "Failed"
+
+	specPatch, statusPatch, err := SplitPatchBytes(oldObj, newObj)
+	if err != nil {
+		t.Fatalf("SplitPatchBytes returned error: %v", err)
+	}
+	if specPatch != nil {
+		t.Errorf("specPatch = %s, want nil since only status changed", specPatch)
+	}
+
+	var decoded map[string]map[string]interface{}
+	if err := json.Unmarshal(statusPatch, &decoded); err != nil {
+		t.Fatalf("unmarshal statusPatch: %v", err)
+	}
+	if _, ok := decoded["spec"]; ok {
+		t.Errorf("statusPatch %s unexpectedly carries a spec field", statusPatch)
+	}
+	if decoded["status"]["phase"] != "Failed" {
+		t.Errorf("statusPatch %s missing expected status.phase=Failed", statusPatch)
+	}
+}
+
+func (f *fakeObject) DeepCopy() *fakeObject {
+	cp := *f
+	return &cp
+}